@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+
 	"group-bestStructure/internal/user"
 	"group-bestStructure/pkg/errors"
 	"group-bestStructure/pkg/logger"
@@ -35,6 +37,16 @@ type AuthConfig struct {
 	AccessTokenExpiry  time.Duration // 访问令牌有效期
 	RefreshTokenExpiry time.Duration // 刷新令牌有效期
 	Issuer             string        // 颁发者
+
+	// ClaimsEnricher 在签发访问令牌前对基础 claims 做一次加工，为 nil 时不做任何改动
+	ClaimsEnricher func(base jwt.MapClaims) jwt.MapClaims
+
+	// AccessKeyProvider / RefreshKeyProvider 决定访问/刷新令牌用什么算法、密钥签名和验证，为 nil 时回退为 HMAC
+	AccessKeyProvider  KeyProvider
+	RefreshKeyProvider KeyProvider
+
+	// AllowedAlgs 是验证令牌时允许的签名算法白名单，为空时回退到 KeyProvider 自己的默认算法
+	AllowedAlgs []string
 }
 
 // Service 认证服务接口
@@ -42,6 +54,13 @@ type Service interface {
 	// Login 用户登录
 	Login(ctx context.Context, email, password string) (*LoginResponse, error)
 
+	// Authorize 按 OAuth2 grant_type 分发授权请求，password/refresh_token 等均走这个入口
+	Authorize(ctx context.Context, grantType string, params map[string]string) (*LoginResponse, error)
+
+	// RegisterGrant 注册一个 grant_type 对应的处理器，供调用方接入 sms_captcha、wechat_code
+	// 等自定义授权方式，而无需修改这个包
+	RegisterGrant(name string, h GrantHandler)
+
 	// RefreshToken 刷新访问令牌
 	RefreshToken(ctx context.Context, refreshToken string, fingerprint string) (*LoginResponse, error)
 
@@ -51,6 +70,9 @@ type Service interface {
 	// ValidateToken 验证令牌
 	ValidateToken(ctx context.Context, tokenString string) (*TokenInfo, error)
 
+	// Introspect 按 RFC 7662 返回令牌的内省信息，供上游网关在不持有签名密钥的情况下校验令牌
+	Introspect(ctx context.Context, token string) (*IntrospectionResponse, error)
+
 	// LogoutAllDevices 登出所有设备
 	LogoutAllDevices(ctx context.Context, userID string) error
 
@@ -80,6 +102,7 @@ type AuthService struct {
 	userService user.Service
 	userRepo    user.Repository
 	log         *logger.Logger
+	grants      map[string]GrantHandler
 }
 
 // NewService 创建新的认证服务
@@ -93,12 +116,25 @@ func NewService(
 		"module": "auth_service",
 	})
 
-	return &AuthService{
+	if config.AccessKeyProvider == nil {
+		config.AccessKeyProvider = NewHMACKeyProvider("access", []byte(config.AccessTokenSecret))
+	}
+	if config.RefreshKeyProvider == nil {
+		config.RefreshKeyProvider = NewHMACKeyProvider("refresh", []byte(config.RefreshTokenSecret))
+	}
+
+	s := &AuthService{
 		config:      config,
 		repository:  repository,
 		userService: userService,
 		userRepo:    userRepo,
 		log:         log,
+		grants:      make(map[string]GrantHandler),
 	}
+
+	s.RegisterGrant(GrantTypePassword, &passwordGrantHandler{service: s})
+	s.RegisterGrant(GrantTypeRefreshToken, &refreshTokenGrantHandler{service: s})
+
+	return s
 }
 
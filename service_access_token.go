@@ -2,10 +2,11 @@ package auth
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 
 	"group-bestStructure/pkg/errors"
 	"group-bestStructure/pkg/logger"
@@ -34,7 +35,7 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*T
 
 	if token.Status == TokenStatusRevoked {
 		s.log.Warn("令牌已被撤销", logger.String("user_id", token.UserID))
-		return nil, ErrTokenRevoked
+		return nil, &RevokedTokenError{Reason: "revoked"}
 	}
 
 	// 检查令牌是否过期
@@ -67,7 +68,7 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*T
 		s.log.Warn("令牌在黑名单中",
 			logger.String("token", maskToken(tokenString)),
 			logger.String("reason", string(reason)))
-		return nil, ErrTokenRevoked
+		return nil, &RevokedTokenError{Reason: string(reason)}
 	}
 
 	// 记录令牌访问
@@ -87,33 +88,30 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*T
 	_ = s.repository.RecordTokenAccess(ctx, tokenString, token.UserID, deviceID)
 
 	// 验证JWT
-	claims := &struct {
-		UserID string `json:"user_id"`
-		Email  string `json:"email"`
-		jwt.StandardClaims
-	}{}
+	claims := jwt.MapClaims{}
 
-	jwtKey := []byte(s.config.AccessTokenSecret)
+	keyProvider := s.config.AccessKeyProvider
 	if token.TokenKind == RefreshToken {
-		jwtKey = []byte(s.config.RefreshTokenSecret)
+		keyProvider = s.config.RefreshKeyProvider
 	}
-
-	jwtToken, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			s.log.Warn("无效的令牌签名方法",
-				logger.String("method", token.Method.Alg()))
-			return nil, fmt.Errorf("无效的签名方法: %v", token.Header["alg"])
+	allowedAlgs := allowedAlgsOrDefault(s.config, keyProvider)
+
+	jwtToken, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		// 拒绝 alg=none，并按 AllowedAlgs 白名单校验签名算法，而不是只认 HS256
+		alg, _ := t.Header["alg"].(string)
+		if alg == "" || alg == "none" || !allowedAlgs[alg] {
+			s.log.Warn("不被允许的令牌签名算法", logger.String("alg", alg))
+			return nil, fmt.Errorf("不被允许的签名算法: %s", alg)
 		}
-		return jwtKey, nil
+
+		kid, _ := t.Header["kid"].(string)
+		return keyProvider.VerifyKey(ctx, kid)
 	})
 
 	if err != nil {
-		if ve, ok := err.(*jwt.ValidationError); ok {
-			if ve.Errors&jwt.ValidationErrorExpired != 0 {
-				s.log.Warn("JWT令牌已过期", logger.Err(err))
-				return nil, ErrTokenExpired
-			}
+		if stderrors.Is(err, jwt.ErrTokenExpired) {
+			s.log.Warn("JWT令牌已过期", logger.Err(err))
+			return nil, ErrTokenExpired
 		}
 		s.log.Error("解析JWT令牌失败", logger.Err(err))
 		return nil, ErrTokenInvalid
@@ -131,11 +129,19 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*T
 		email = user.Email
 	}
 
+	extra := make(map[string]any)
+	for k, v := range claims {
+		if !standardClaimKeys[k] {
+			extra[k] = v
+		}
+	}
+
 	tokenInfo := &TokenInfo{
 		UserID:    token.UserID,
 		Email:     email,
-		ExpiresAt: claims.ExpiresAt,
-		IssuedAt:  claims.IssuedAt,
+		ExpiresAt: int64ClaimOr(claims, "exp", 0),
+		IssuedAt:  int64ClaimOr(claims, "iat", 0),
+		Extra:     extra,
 	}
 
 	s.log.Info("令牌验证成功",
@@ -145,6 +151,19 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*T
 	return tokenInfo, nil
 }
 
+// RevokedTokenError 是 ErrTokenRevoked 的一个变体，额外携带撤销原因供 Introspect 复用
+type RevokedTokenError struct {
+	Reason string
+}
+
+func (e *RevokedTokenError) Error() string {
+	return ErrTokenRevoked.Error() + ": " + e.Reason
+}
+
+func (e *RevokedTokenError) Unwrap() error {
+	return ErrTokenRevoked
+}
+
 // IsTokenRevoked 检查令牌是否已被撤销
 func (s *AuthService) IsTokenRevoked(ctx context.Context, tokenValue string) (bool, error) {
 	s.log.Debug("检查令牌是否被撤销", logger.String("token", maskToken(tokenValue)))
@@ -192,17 +211,32 @@ func (s *AuthService) InvalidateTokenCache(ctx context.Context, tokenValue strin
 // generateAccessToken 生成访问令牌
 func (s *AuthService) generateAccessToken(userID, email string, fingerprintHash string) (string, error) {
 	expirationTime := time.Now().Add(s.config.AccessTokenExpiry)
-	claims := &jwt.StandardClaims{
-		ExpiresAt: expirationTime.Unix(),
-		IssuedAt:  time.Now().Unix(),
-		Issuer:    s.config.Issuer,
-		Subject:   userID,
-		Id:        fingerprintHash,
-		Audience:  email,
+	claims := jwt.MapClaims{
+		"exp": expirationTime.Unix(),
+		"iat": time.Now().Unix(),
+		"iss": s.config.Issuer,
+		"sub": userID,
+		"jti": fingerprintHash,
+		"aud": email,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.config.AccessTokenSecret))
+	// 扩展字段（tenant_id、roles、device_id 等）由调用方通过 ClaimsEnricher 注入，
+	// 这里不关心具体内容
+	if s.config.ClaimsEnricher != nil {
+		claims = s.config.ClaimsEnricher(claims)
+	}
+
+	kid, method, key, err := s.config.AccessKeyProvider.SigningKey(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("获取签名密钥失败: %w", err)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", err
 	}
@@ -210,6 +244,27 @@ func (s *AuthService) generateAccessToken(userID, email string, fingerprintHash
 	return tokenString, nil
 }
 
+// standardClaimKeys 是 generateAccessToken 写入的标准字段，剩下的都算扩展字段，
+// 在 ValidateToken 中回填到 TokenInfo.Extra
+var standardClaimKeys = map[string]bool{
+	"exp": true, "iat": true, "iss": true,
+	"sub": true, "jti": true, "aud": true,
+}
+
+// int64ClaimOr 从 jwt.MapClaims 中取出数值型字段，JSON 解码后数字是 float64，
+// 取不到或类型不对时返回 def
+func int64ClaimOr(claims jwt.MapClaims, key string, def int64) int64 {
+	v, ok := claims[key]
+	if !ok {
+		return def
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return def
+	}
+	return int64(f)
+}
+
 // maskToken 对令牌进行掩码处理，用于日志记录
 func maskToken(token string) string {
 	if len(token) < 10 {
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"group-bestStructure/pkg/errors"
+	"group-bestStructure/pkg/logger"
+)
+
+// RFC 6749 定义的标准 grant_type，以及本系统扩展的短信验证码、微信授权码
+const (
+	GrantTypePassword          = "password"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeSMSCaptcha        = "sms_captcha"
+	GrantTypeWeChatCode        = "wechat_code"
+)
+
+// ErrUnsupportedGrantType 对应未注册的 grant_type
+var ErrUnsupportedGrantType = errors.New(errors.ErrCodeBadRequest).WithMessage("不支持的授权类型")
+
+// GrantHandler 处理一种 OAuth2 grant_type 的授权请求，统一输出 LoginResponse
+type GrantHandler interface {
+	Handle(ctx context.Context, params map[string]string) (*LoginResponse, error)
+}
+
+// RegisterGrant 注册一个 grant_type 对应的处理器，后注册的会覆盖先注册的同名 grant
+func (s *AuthService) RegisterGrant(name string, h GrantHandler) {
+	s.grants[name] = h
+}
+
+// Authorize 按 grant_type 分发到对应的 GrantHandler，是所有授权方式的统一入口
+func (s *AuthService) Authorize(ctx context.Context, grantType string, params map[string]string) (*LoginResponse, error) {
+	handler, ok := s.grants[grantType]
+	if !ok {
+		s.log.Warn("未注册的授权类型", logger.String("grant_type", grantType))
+		return nil, ErrUnsupportedGrantType
+	}
+
+	return handler.Handle(ctx, params)
+}
+
+// passwordGrantHandler 实现 password grant，即 (email, password) 登录
+type passwordGrantHandler struct {
+	service *AuthService
+}
+
+func (h *passwordGrantHandler) Handle(ctx context.Context, params map[string]string) (*LoginResponse, error) {
+	email := params["email"]
+	password := params["password"]
+	if email == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	return h.service.loginWithPassword(ctx, email, password)
+}
+
+// refreshTokenGrantHandler 实现 refresh_token grant，包一层已有的 RefreshToken
+type refreshTokenGrantHandler struct {
+	service *AuthService
+}
+
+func (h *refreshTokenGrantHandler) Handle(ctx context.Context, params map[string]string) (*LoginResponse, error) {
+	refreshToken := params["refresh_token"]
+	if refreshToken == "" {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	return h.service.RefreshToken(ctx, refreshToken, params["fingerprint"])
+}
+
+// Login 用户登录，保留作为 password grant 的瘦包装，兼容既有调用方
+func (s *AuthService) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+	return s.Authorize(ctx, GrantTypePassword, map[string]string{
+		"email":    email,
+		"password": password,
+	})
+}
+
+// loginWithPassword 校验邮箱密码并签发令牌，是 password grant 的核心逻辑
+func (s *AuthService) loginWithPassword(ctx context.Context, email, password string) (*LoginResponse, error) {
+	s.log.Debug("邮箱密码登录", logger.String("email", email))
+
+	u, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		s.log.Error("获取用户信息失败", logger.Err(err))
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError)
+	}
+	if u == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.userService.VerifyPassword(ctx, u.ID, password); err != nil {
+		s.log.Warn("密码校验失败", logger.String("user_id", u.ID))
+		return nil, ErrInvalidCredentials
+	}
+
+	if u.Locked {
+		return nil, ErrUserLocked
+	}
+	if u.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	accessToken, err := s.generateAccessToken(u.ID, u.Email, "")
+	if err != nil {
+		return nil, fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+
+	return &LoginResponse{
+		AccessToken: accessToken,
+		UserID:      u.ID,
+		Email:       u.Email,
+	}, nil
+}
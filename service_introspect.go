@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+
+	"group-bestStructure/pkg/logger"
+)
+
+// IntrospectionResponse 是 RFC 7662 定义的令牌内省响应
+type IntrospectionResponse struct {
+	Active   bool           `json:"active"`
+	Sub      string         `json:"sub,omitempty"`
+	Aud      string         `json:"aud,omitempty"`
+	Exp      int64          `json:"exp,omitempty"`
+	Iat      int64          `json:"iat,omitempty"`
+	Iss      string         `json:"iss,omitempty"`
+	ClientID string         `json:"client_id,omitempty"`
+	Scope    string         `json:"scope,omitempty"`
+	// RevocationReason 仅在令牌曾被主动撤销时填充，过期等其他失效原因留空
+	RevocationReason string `json:"revocation_reason,omitempty"`
+}
+
+// Introspect 按 RFC 7662 返回令牌的内省信息
+func (s *AuthService) Introspect(ctx context.Context, tokenString string) (*IntrospectionResponse, error) {
+	s.log.Debug("内省令牌", logger.String("token", maskToken(tokenString)))
+
+	if tokenString == "" {
+		return &IntrospectionResponse{Active: false}, nil
+	}
+
+	info, err := s.ValidateToken(ctx, tokenString)
+	if err != nil {
+		var revoked *RevokedTokenError
+		if stderrors.As(err, &revoked) {
+			return &IntrospectionResponse{Active: false, RevocationReason: revoked.Reason}, nil
+		}
+		return &IntrospectionResponse{Active: false}, nil
+	}
+
+	return &IntrospectionResponse{
+		Active:   true,
+		Sub:      info.UserID,
+		Aud:      info.Email,
+		Exp:      info.ExpiresAt,
+		Iat:      info.IssuedAt,
+		Iss:      s.config.Issuer,
+		ClientID: stringExtra(info.Extra, "client_id"),
+		Scope:    stringExtra(info.Extra, "scope"),
+	}, nil
+}
+
+// stringExtra 从 TokenInfo.Extra 中安全取出一个字符串扩展字段
+func stringExtra(extra map[string]any, key string) string {
+	v, ok := extra[key].(string)
+	if !ok {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,336 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyProvider 把签名/验证 JWT 用的算法与密钥材料从硬编码的 HS256 静态密钥里解耦出来
+type KeyProvider interface {
+	// SigningKey 返回当前用于签发新令牌的 kid、签名方法与密钥材料
+	SigningKey(ctx context.Context) (kid string, method jwt.SigningMethod, key interface{}, err error)
+	// VerifyKey 按 kid 返回验证令牌签名用的公钥/密钥材料
+	VerifyKey(ctx context.Context, kid string) (interface{}, error)
+	// DefaultAlgs 返回这个 provider 实际签发/验证时使用的算法，AllowedAlgs 未配置时以此为准
+	DefaultAlgs() []string
+}
+
+// HMACKeyProvider 是迁移前的默认行为：单一静态密钥，HS256
+type HMACKeyProvider struct {
+	KeyID  string
+	Secret []byte
+}
+
+// NewHMACKeyProvider 创建一个基于静态密钥的 HMAC KeyProvider
+func NewHMACKeyProvider(keyID string, secret []byte) *HMACKeyProvider {
+	return &HMACKeyProvider{KeyID: keyID, Secret: secret}
+}
+
+func (p *HMACKeyProvider) SigningKey(ctx context.Context) (string, jwt.SigningMethod, interface{}, error) {
+	return p.KeyID, jwt.SigningMethodHS256, p.Secret, nil
+}
+
+func (p *HMACKeyProvider) VerifyKey(ctx context.Context, kid string) (interface{}, error) {
+	return p.Secret, nil
+}
+
+func (p *HMACKeyProvider) DefaultAlgs() []string {
+	return []string{jwt.SigningMethodHS256.Alg()}
+}
+
+// RSAKeyProvider 用 PEM 文件加载的 RSA 密钥对签名/验证，支持多个 kid 同时有效以便轮换
+type RSAKeyProvider struct {
+	KeyID      string
+	PrivateKey *rsa.PrivateKey
+	PublicKeys map[string]*rsa.PublicKey
+}
+
+// LoadRSAKeyProvider 从 PEM 文件加载签名私钥及一组验证公钥
+func LoadRSAKeyProvider(keyID, privateKeyPath string, publicKeyPaths map[string]string) (*RSAKeyProvider, error) {
+	privPEM, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取 RSA 私钥文件失败: %w", err)
+	}
+	privBlock, _ := pem.Decode(privPEM)
+	if privBlock == nil {
+		return nil, fmt.Errorf("RSA 私钥文件不是有效的 PEM: %s", privateKeyPath)
+	}
+	privKey, err := x509.ParsePKCS1PrivateKey(privBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析 RSA 私钥失败: %w", err)
+	}
+
+	publicKeys := make(map[string]*rsa.PublicKey, len(publicKeyPaths))
+	for kid, path := range publicKeyPaths {
+		pubPEM, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取 RSA 公钥文件失败 %s: %w", path, err)
+		}
+		pubBlock, _ := pem.Decode(pubPEM)
+		if pubBlock == nil {
+			return nil, fmt.Errorf("RSA 公钥文件不是有效的 PEM: %s", path)
+		}
+		pubIface, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("解析 RSA 公钥失败: %w", err)
+		}
+		pubKey, ok := pubIface.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s 不是 RSA 公钥", path)
+		}
+		publicKeys[kid] = pubKey
+	}
+
+	return &RSAKeyProvider{KeyID: keyID, PrivateKey: privKey, PublicKeys: publicKeys}, nil
+}
+
+func (p *RSAKeyProvider) SigningKey(ctx context.Context) (string, jwt.SigningMethod, interface{}, error) {
+	return p.KeyID, jwt.SigningMethodRS256, p.PrivateKey, nil
+}
+
+func (p *RSAKeyProvider) VerifyKey(ctx context.Context, kid string) (interface{}, error) {
+	key, ok := p.PublicKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("未知的 kid: %s", kid)
+	}
+	return key, nil
+}
+
+func (p *RSAKeyProvider) DefaultAlgs() []string {
+	return []string{jwt.SigningMethodRS256.Alg()}
+}
+
+// ECDSAKeyProvider 用 PEM 文件加载的 ECDSA 密钥对签名/验证
+type ECDSAKeyProvider struct {
+	KeyID      string
+	PrivateKey *ecdsa.PrivateKey
+	PublicKeys map[string]*ecdsa.PublicKey
+}
+
+// LoadECDSAKeyProvider 从 PEM 文件加载签名私钥及一组验证公钥
+func LoadECDSAKeyProvider(keyID, privateKeyPath string, publicKeyPaths map[string]string) (*ECDSAKeyProvider, error) {
+	privPEM, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取 ECDSA 私钥文件失败: %w", err)
+	}
+	privBlock, _ := pem.Decode(privPEM)
+	if privBlock == nil {
+		return nil, fmt.Errorf("ECDSA 私钥文件不是有效的 PEM: %s", privateKeyPath)
+	}
+	privKey, err := x509.ParseECPrivateKey(privBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析 ECDSA 私钥失败: %w", err)
+	}
+	if _, err := ecdsaSigningMethod(privKey); err != nil {
+		return nil, err
+	}
+
+	publicKeys := make(map[string]*ecdsa.PublicKey, len(publicKeyPaths))
+	for kid, path := range publicKeyPaths {
+		pubPEM, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取 ECDSA 公钥文件失败 %s: %w", path, err)
+		}
+		pubBlock, _ := pem.Decode(pubPEM)
+		if pubBlock == nil {
+			return nil, fmt.Errorf("ECDSA 公钥文件不是有效的 PEM: %s", path)
+		}
+		pubIface, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("解析 ECDSA 公钥失败: %w", err)
+		}
+		pubKey, ok := pubIface.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s 不是 ECDSA 公钥", path)
+		}
+		publicKeys[kid] = pubKey
+	}
+
+	return &ECDSAKeyProvider{KeyID: keyID, PrivateKey: privKey, PublicKeys: publicKeys}, nil
+}
+
+func (p *ECDSAKeyProvider) SigningKey(ctx context.Context) (string, jwt.SigningMethod, interface{}, error) {
+	method, err := ecdsaSigningMethod(p.PrivateKey)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return p.KeyID, method, p.PrivateKey, nil
+}
+
+// ecdsaSigningMethod 按私钥实际使用的曲线选择对应的 jwt.SigningMethod（P-256/P-384/P-521）
+func ecdsaSigningMethod(key *ecdsa.PrivateKey) (jwt.SigningMethod, error) {
+	switch key.Curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	default:
+		return nil, fmt.Errorf("不支持的 ECDSA 曲线: %s", key.Curve.Params().Name)
+	}
+}
+
+func (p *ECDSAKeyProvider) VerifyKey(ctx context.Context, kid string) (interface{}, error) {
+	key, ok := p.PublicKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("未知的 kid: %s", kid)
+	}
+	return key, nil
+}
+
+func (p *ECDSAKeyProvider) DefaultAlgs() []string {
+	method, err := ecdsaSigningMethod(p.PrivateKey)
+	if err != nil {
+		return nil
+	}
+	return []string{method.Alg()}
+}
+
+// jwksDocument 对应 JWKS 端点返回的 JSON 结构（仅保留校验 RSA 签名所需字段）
+type jwksDocument struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// JWKSKeyProvider 从外部 OIDC issuer 的 `/.well-known/jwks.json` 拉取公钥并按 kid 查找缓存，只用于验证
+type JWKSKeyProvider struct {
+	endpoint   string
+	httpClient *http.Client
+	cacheTTL   time.Duration
+
+	mu       sync.RWMutex
+	cachedAt time.Time
+	keys     map[string]*rsa.PublicKey
+}
+
+// NewJWKSKeyProvider 创建一个指向 issuer JWKS 端点的 KeyProvider
+func NewJWKSKeyProvider(endpoint string, cacheTTL time.Duration) *JWKSKeyProvider {
+	return &JWKSKeyProvider{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cacheTTL:   cacheTTL,
+	}
+}
+
+func (p *JWKSKeyProvider) SigningKey(ctx context.Context) (string, jwt.SigningMethod, interface{}, error) {
+	return "", nil, nil, fmt.Errorf("JWKS key provider 只用于验证外部签发的令牌，不支持签发")
+}
+
+func (p *JWKSKeyProvider) DefaultAlgs() []string {
+	return []string{jwt.SigningMethodRS256.Alg()}
+}
+
+func (p *JWKSKeyProvider) VerifyKey(ctx context.Context, kid string) (interface{}, error) {
+	keys, err := p.loadKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("JWKS 中未找到 kid: %s", kid)
+	}
+	return key, nil
+}
+
+func (p *JWKSKeyProvider) loadKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	p.mu.RLock()
+	if p.keys != nil && time.Since(p.cachedAt) < p.cacheTTL {
+		keys := p.keys
+		p.mu.RUnlock()
+		return keys, nil
+	}
+	p.mu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("构造 JWKS 请求失败: %w", err)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("获取 JWKS 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取 JWKS 响应失败: %w", err)
+	}
+
+	var doc jwksDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("解析 JWKS 响应失败: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pubKey, err := rsaPublicKeyFromJWK(k.N, k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pubKey
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.cachedAt = time.Now()
+	p.mu.Unlock()
+
+	return keys, nil
+}
+
+// rsaPublicKeyFromJWK 把 JWK 里 base64url 编码的模数(n)和指数(e)还原成 *rsa.PublicKey
+func rsaPublicKeyFromJWK(nEncoded, eEncoded string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(nEncoded)
+	if err != nil {
+		return nil, fmt.Errorf("解码 JWK n 失败: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(eEncoded)
+	if err != nil {
+		return nil, fmt.Errorf("解码 JWK e 失败: %w", err)
+	}
+
+	n := new(big.Int).SetBytes(nBytes)
+	e := new(big.Int).SetBytes(eBytes)
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// allowedAlgsOrDefault 返回配置的算法允许列表，未配置时回退到 provider 自己实际使用的算法
+func allowedAlgsOrDefault(cfg *AuthConfig, provider KeyProvider) map[string]bool {
+	algs := cfg.AllowedAlgs
+	if len(algs) == 0 && provider != nil {
+		algs = provider.DefaultAlgs()
+	}
+
+	allowed := make(map[string]bool, len(algs))
+	for _, alg := range algs {
+		allowed[alg] = true
+	}
+	if len(allowed) == 0 {
+		allowed[jwt.SigningMethodHS256.Alg()] = true
+	}
+	return allowed
+}
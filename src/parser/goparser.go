@@ -3,11 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"go/ast"
-	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
 )
 
 // 接口方法信息
@@ -17,13 +18,23 @@ type MethodInfo struct {
 	FilePath string `json:"filePath"`
 }
 
+// TypeParamInfo 描述一个类型参数，例如 Store[K comparable, V any] 中的 K 和 V
+type TypeParamInfo struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+}
+
 // 接口定义信息
 type InterfaceInfo struct {
-	Name         string       `json:"name"`
-	Line         int          `json:"line"`
-	FilePath     string       `json:"filePath"`
-	Methods      []MethodInfo `json:"methods"`
-	InternalType string       `json:"internalType,omitempty"` // 可能的内嵌接口名
+	Name     string       `json:"name"`
+	Line     int          `json:"line"`
+	FilePath string       `json:"filePath"`
+	Methods  []MethodInfo `json:"methods"`
+	// InternalType 保留给旧插件使用，只记录第一个内嵌接口名。
+	// 新代码应读取 EmbeddedInterfaces，它记录了全部内嵌接口。
+	InternalType       string          `json:"internalType,omitempty"`
+	EmbeddedInterfaces []string        `json:"embeddedInterfaces,omitempty"`
+	TypeParams         []TypeParamInfo `json:"typeParams,omitempty"`
 }
 
 // 结构体字段信息
@@ -38,19 +49,32 @@ type FieldInfo struct {
 
 // 结构体定义信息
 type StructInfo struct {
-	Name     string      `json:"name"`
-	Line     int         `json:"line"`
-	FilePath string      `json:"filePath"`
-	Fields   []FieldInfo `json:"fields"`
+	Name       string          `json:"name"`
+	Line       int             `json:"line"`
+	FilePath   string          `json:"filePath"`
+	Fields     []FieldInfo     `json:"fields"`
+	TypeParams []TypeParamInfo `json:"typeParams,omitempty"`
 }
 
 // 方法实现信息
 type ImplementationInfo struct {
-	ReceiverType string `json:"receiverType"`
-	MethodName   string `json:"methodName"`
-	Line         int    `json:"line"`
-	FilePath     string `json:"filePath"`
-	IsPointer    bool   `json:"isPointer"`
+	// ReceiverType 对泛型接收者（如 func (s *Store[K, V]) Get(...)）会归一化为声明时的
+	// 类型参数名，例如 "Store[K,V]"，而不是调用点各不相同的实例化写法。
+	ReceiverType string          `json:"receiverType"`
+	MethodName   string          `json:"methodName"`
+	Line         int             `json:"line"`
+	FilePath     string          `json:"filePath"`
+	IsPointer    bool            `json:"isPointer"`
+	TypeParams   []TypeParamInfo `json:"typeParams,omitempty"`
+}
+
+// ImplementationMatch 描述一个经 types.Implements 判定满足某接口的具体类型
+type ImplementationMatch struct {
+	PackagePath string `json:"packagePath"`
+	TypeName    string `json:"typeName"`
+	IsPointer   bool   `json:"isPointer"` // 是通过 *T 还是 T 满足的接口
+	FilePath    string `json:"filePath"`
+	Line        int    `json:"line"`
 }
 
 // 包信息
@@ -65,209 +89,402 @@ type PackageInfo struct {
 // 解析结果
 type ParseResult struct {
 	Packages map[string]PackageInfo `json:"packages"`
+	// Implementations 以接口的限定名（包路径.接口名）为键，列出所有满足该接口的具体类型，
+	// 覆盖 Packages 中的全部包，包含内嵌方法以及值/指针接收者的区别。只作为依赖加载、
+	// 不在 Packages 中的包，其接口和类型都不参与匹配。
+	Implementations map[string][]ImplementationMatch `json:"implementations"`
 }
 
-// 从文件位置获取行号
-func getLineFromPos(fset *token.FileSet, pos token.Pos) int {
-	// 返回行号减1，使装饰显示在方法定义行
-	return fset.Position(pos).Line - 1
+// qualifiedName 返回接口的限定名，用于在 Implementations 中做唯一键
+func qualifiedName(pkgPath, name string) string {
+	return pkgPath + "." + name
 }
 
-// 从类型表达式中提取类型名
-func getTypeNameFromExpr(expr ast.Expr) (name string, isPointer bool) {
-	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name, false
-	case *ast.StarExpr:
-		if ident, ok := t.X.(*ast.Ident); ok {
-			return ident.Name, true
-		}
-	case *ast.SelectorExpr:
-		if ident, ok := t.X.(*ast.Ident); ok {
-			return ident.Name + "." + t.Sel.Name, false
+// typeParamsOf 提取泛型类型声明时的类型参数列表（名字 + 约束表达式），非泛型类型返回 nil
+func typeParamsOf(named *types.Named) []TypeParamInfo {
+	tparams := named.TypeParams()
+	if tparams == nil || tparams.Len() == 0 {
+		return nil
+	}
+
+	result := make([]TypeParamInfo, 0, tparams.Len())
+	for i := 0; i < tparams.Len(); i++ {
+		tp := tparams.At(i)
+		result = append(result, TypeParamInfo{
+			Name:       tp.Obj().Name(),
+			Constraint: tp.Constraint().String(),
+		})
+	}
+	return result
+}
+
+// displayName 把裸类型名和类型参数列表拼成编辑器展示用的形式，如 "Store[K,V]"
+func displayName(name string, typeParams []TypeParamInfo) string {
+	if len(typeParams) == 0 {
+		return name
+	}
+	names := make([]string, len(typeParams))
+	for i, tp := range typeParams {
+		names[i] = tp.Name
+	}
+	joined := ""
+	for i, n := range names {
+		if i > 0 {
+			joined += ","
 		}
+		joined += n
 	}
-	return "", false
+	return name + "[" + joined + "]"
 }
 
-// 递归解析目录下的Go文件
-func parseDirectory(dirPath string) (ParseResult, error) {
-	result := ParseResult{
-		Packages: make(map[string]PackageInfo),
+// loadPackages 使用 go/packages + go/types 加载 dirPath 所在包及其全部依赖
+func loadPackages(dirPath string) ([]*packages.Package, error) {
+	return loadPackagePatterns(dirPath, []string{"."})
+}
+
+// loadPackagePatterns 是 loadPackages 的通用版本，patterns 支持 "./..." 等 go list 模式
+func loadPackagePatterns(dirPath string, patterns []string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedImports | packages.NeedDeps | packages.NeedTypes |
+			packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir: dirPath,
 	}
 
-	// 创建一个已处理目录的集合，避免重复处理
-	processedDirs := make(map[string]bool)
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("加载包失败: %w", err)
+	}
 
-	// 递归处理同一个包中的所有Go文件
-	processDir := func(dir string) error {
-		// 避免重复处理同一目录
-		if processedDirs[dir] {
-			return nil
+	var loadErrs []error
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			loadErrs = append(loadErrs, e)
 		}
-		processedDirs[dir] = true
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("目录 %s 下未找到任何包", dirPath)
+	}
+	if len(loadErrs) > 0 {
+		// 部分文件解析失败不应阻断整体分析，记录到 stderr 继续处理
+		for _, e := range loadErrs {
+			fmt.Fprintf(os.Stderr, "包加载警告: %v\n", e)
+		}
+	}
 
-		// 查找同包下的所有Go文件
-		goFiles, err := filepath.Glob(filepath.Join(dir, "*.go"))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "查找Go文件失败 %s: %v\n", dir, err)
-			return nil // 继续处理其他目录
+	return pkgs, nil
+}
+
+// extractFacts 从已加载的包中抽取 InterfaceInfo / StructInfo / ImplementationInfo，
+// 并返回每个包作用域内 *types.Named 的索引，供接口实现判定使用
+func extractFacts(pkgs []*packages.Package, result *ParseResult) map[*types.Named]struct {
+	pkgPath  string
+	filePath string
+	line     int
+} {
+	namedIndex := make(map[*types.Named]struct {
+		pkgPath  string
+		filePath string
+		line     int
+	})
+
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
 		}
 
-		// 解析当前目录中的所有Go文件
-		for _, path := range goFiles {
-			fset := token.NewFileSet()
-			node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "解析文件失败 %s: %v\n", path, err)
-				continue // 继续处理其他文件
+		pkgInfo := PackageInfo{
+			Path:       pkg.PkgPath,
+			Name:       pkg.Name,
+			Interfaces: []InterfaceInfo{},
+			Structs:    []StructInfo{},
+			Methods:    []ImplementationInfo{},
+		}
+
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				continue
 			}
 
-			packageName := node.Name.Name
-			packagePath := filepath.Dir(path)
-
-			// 确保包信息存在
-			if _, exists := result.Packages[packagePath]; !exists {
-				result.Packages[packagePath] = PackageInfo{
-					Path:       packagePath,
-					Name:       packageName,
-					Interfaces: []InterfaceInfo{},
-					Structs:    []StructInfo{},
-					Methods:    []ImplementationInfo{},
-				}
+			pos := pkg.Fset.Position(obj.Pos())
+			line := pos.Line - 1
+			filePath := pos.Filename
+
+			namedIndex[named] = struct {
+				pkgPath  string
+				filePath string
+				line     int
+			}{pkgPath: pkg.PkgPath, filePath: filePath, line: line}
+
+			typeParams := typeParamsOf(named)
+
+			switch underlying := named.Underlying().(type) {
+			case *types.Interface:
+				interfaceInfo := buildInterfaceInfo(pkg, obj.Name(), filePath, line, underlying)
+				interfaceInfo.TypeParams = typeParams
+				pkgInfo.Interfaces = append(pkgInfo.Interfaces, interfaceInfo)
+			case *types.Struct:
+				structInfo := buildStructInfo(pkg.Fset, obj.Name(), filePath, line, underlying)
+				structInfo.TypeParams = typeParams
+				pkgInfo.Structs = append(pkgInfo.Structs, structInfo)
+			}
+
+			// 收集该类型（及其指针类型）的方法实现
+			collectMethods(pkg, named, &pkgInfo.Methods)
+		}
+
+		result.Packages[pkg.PkgPath] = pkgInfo
+	}
+
+	return namedIndex
+}
+
+// buildInterfaceInfo 构造 InterfaceInfo，记录接口自身声明的方法和全部内嵌接口
+func buildInterfaceInfo(pkg *packages.Package, name, filePath string, line int, iface *types.Interface) InterfaceInfo {
+	interfaceInfo := InterfaceInfo{
+		Name:     name,
+		Line:     line,
+		FilePath: filePath,
+		Methods:  []MethodInfo{},
+	}
+
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		m := iface.ExplicitMethod(i)
+		mPos := pkg.Fset.Position(m.Pos())
+		interfaceInfo.Methods = append(interfaceInfo.Methods, MethodInfo{
+			Name:     m.Name(),
+			Line:     mPos.Line - 1,
+			FilePath: mPos.Filename,
+		})
+	}
+
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		embedded := iface.EmbeddedType(i)
+		var embeddedName string
+		if named, ok := embedded.(*types.Named); ok {
+			obj := named.Obj()
+			embeddedName = obj.Name()
+			if obj.Pkg() != nil {
+				embeddedName = obj.Pkg().Path() + "." + obj.Name()
 			}
+		} else {
+			embeddedName = embedded.String()
+		}
+		interfaceInfo.EmbeddedInterfaces = append(interfaceInfo.EmbeddedInterfaces, embeddedName)
+		if interfaceInfo.InternalType == "" {
+			interfaceInfo.InternalType = embeddedName
+		}
+	}
+
+	return interfaceInfo
+}
+
+// buildStructInfo 构造 StructInfo
+func buildStructInfo(fset *token.FileSet, name, filePath string, line int, st *types.Struct) StructInfo {
+	structInfo := StructInfo{
+		Name:     name,
+		Line:     line,
+		FilePath: filePath,
+		Fields:   []FieldInfo{},
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		typeName, isPointer := typeNameOf(field.Type())
+		fieldPos := fset.Position(field.Pos())
+		structInfo.Fields = append(structInfo.Fields, FieldInfo{
+			Name:      field.Name(),
+			Type:      typeName,
+			Line:      fieldPos.Line - 1,
+			FilePath:  fieldPos.Filename,
+			Embedded:  field.Embedded(),
+			IsPointer: isPointer,
+		})
+	}
+
+	return structInfo
+}
 
-			pkgInfo := result.Packages[packagePath]
-
-			// 解析接口和结构体
-			ast.Inspect(node, func(n ast.Node) bool {
-				switch decl := n.(type) {
-				case *ast.GenDecl:
-					if decl.Tok == token.TYPE {
-						for _, spec := range decl.Specs {
-							if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-								// 解析接口
-								if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
-									interfaceInfo := InterfaceInfo{
-										Name:     typeSpec.Name.Name,
-										Line:     getLineFromPos(fset, typeSpec.Pos()),
-										FilePath: path,
-										Methods:  []MethodInfo{},
-									}
-
-									// 解析接口方法
-									for _, field := range interfaceType.Methods.List {
-										if len(field.Names) > 0 {
-											// 命名方法
-											for _, name := range field.Names {
-												methodInfo := MethodInfo{
-													Name:     name.Name,
-													Line:     getLineFromPos(fset, field.Pos()),
-													FilePath: path,
-												}
-												interfaceInfo.Methods = append(interfaceInfo.Methods, methodInfo)
-											}
-										} else {
-											// 嵌入接口
-											typeName, _ := getTypeNameFromExpr(field.Type)
-											if typeName != "" {
-												interfaceInfo.InternalType = typeName
-											}
-										}
-									}
-
-									pkgInfo.Interfaces = append(pkgInfo.Interfaces, interfaceInfo)
-								}
-
-								// 解析结构体
-								if structType, ok := typeSpec.Type.(*ast.StructType); ok {
-									structInfo := StructInfo{
-										Name:     typeSpec.Name.Name,
-										Line:     getLineFromPos(fset, typeSpec.Pos()),
-										FilePath: path,
-										Fields:   []FieldInfo{},
-									}
-
-									// 解析结构体字段
-									for _, field := range structType.Fields.List {
-										typeName, isPointer := getTypeNameFromExpr(field.Type)
-
-										if len(field.Names) == 0 {
-											// 嵌入字段
-											fieldInfo := FieldInfo{
-												Name:      typeName, // 嵌入字段名与类型相同
-												Type:      typeName,
-												Line:      getLineFromPos(fset, field.Pos()),
-												FilePath:  path,
-												Embedded:  true,
-												IsPointer: isPointer,
-											}
-											structInfo.Fields = append(structInfo.Fields, fieldInfo)
-										} else {
-											// 命名字段
-											for _, name := range field.Names {
-												fieldInfo := FieldInfo{
-													Name:      name.Name,
-													Type:      typeName,
-													Line:      getLineFromPos(fset, field.Pos()),
-													FilePath:  path,
-													Embedded:  false,
-													IsPointer: isPointer,
-												}
-												structInfo.Fields = append(structInfo.Fields, fieldInfo)
-											}
-										}
-									}
-
-									pkgInfo.Structs = append(pkgInfo.Structs, structInfo)
-								}
-							}
-						}
-					}
-
-				case *ast.FuncDecl:
-					// 解析方法实现
-					if decl.Recv != nil && len(decl.Recv.List) > 0 {
-						recvField := decl.Recv.List[0]
-						typeName, isPointer := getTypeNameFromExpr(recvField.Type)
-
-						if typeName != "" {
-							methodInfo := ImplementationInfo{
-								ReceiverType: typeName,
-								MethodName:   decl.Name.Name,
-								Line:         getLineFromPos(fset, decl.Pos()),
-								FilePath:     path,
-								IsPointer:    isPointer,
-							}
-							pkgInfo.Methods = append(pkgInfo.Methods, methodInfo)
-						}
-					}
+// typeNameOf 返回类型的显示名以及是否为指针类型
+func typeNameOf(t types.Type) (string, bool) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		name, _ := typeNameOf(ptr.Elem())
+		return name, true
+	}
+	if named, ok := t.(*types.Named); ok {
+		if args := named.TypeArgs(); args != nil && args.Len() > 0 {
+			argNames := make([]string, args.Len())
+			for i := 0; i < args.Len(); i++ {
+				argNames[i], _ = typeNameOf(args.At(i))
+			}
+			joined := ""
+			for i, n := range argNames {
+				if i > 0 {
+					joined += ","
 				}
-				return true
+				joined += n
+			}
+			return named.Obj().Name() + "[" + joined + "]", false
+		}
+		return named.Obj().Name(), false
+	}
+	return t.String(), false
+}
+
+// collectMethods 收集类型 T 与 *T 方法集中声明在当前包的方法，填充到 ImplementationInfo
+func collectMethods(pkg *packages.Package, named *types.Named, out *[]ImplementationInfo) {
+	obj := named.Obj()
+	if obj.Pkg() != pkg.Types {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, mset := range []*types.MethodSet{
+		types.NewMethodSet(named),
+		types.NewMethodSet(types.NewPointer(named)),
+	} {
+		for i := 0; i < mset.Len(); i++ {
+			sel := mset.At(i)
+			fn, ok := sel.Obj().(*types.Func)
+			if !ok || fn.Pkg() != pkg.Types {
+				continue
+			}
+			key := fn.Name()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			recv := fn.Type().(*types.Signature).Recv()
+			_, isPointer := recv.Type().(*types.Pointer)
+			pos := pkg.Fset.Position(fn.Pos())
+			typeParams := typeParamsOf(named)
+
+			*out = append(*out, ImplementationInfo{
+				ReceiverType: displayName(obj.Name(), typeParams),
+				MethodName:   fn.Name(),
+				Line:         pos.Line - 1,
+				FilePath:     pos.Filename,
+				IsPointer:    isPointer,
+				TypeParams:   typeParams,
 			})
+		}
+	}
+}
 
-			// 更新包信息
-			result.Packages[packagePath] = pkgInfo
+// resolveImplementations 对每个已知接口，用 types.Implements 判定所有加载到的具体类型（含 T 与 *T 两种形态）
+func resolveImplementations(pkgs []*packages.Package, namedIndex map[*types.Named]struct {
+	pkgPath  string
+	filePath string
+	line     int
+}, result *ParseResult) {
+	var interfaces []struct {
+		qname string
+		named *types.Named
+		typ   *types.Interface
+	}
+	var concretes []*types.Named
+
+	for named := range namedIndex {
+		if iface, ok := named.Underlying().(*types.Interface); ok {
+			info := namedIndex[named]
+			interfaces = append(interfaces, struct {
+				qname string
+				named *types.Named
+				typ   *types.Interface
+			}{qname: qualifiedName(info.pkgPath, named.Obj().Name()), named: named, typ: iface})
+			continue
 		}
+		concretes = append(concretes, named)
+	}
 
-		return nil
+	for _, ifaceEntry := range interfaces {
+		for _, named := range concretes {
+			info := namedIndex[named]
+
+			unified := unifyTypeParams(named, ifaceEntry.named)
+
+			if types.Implements(unified, ifaceEntry.typ) {
+				result.Implementations[ifaceEntry.qname] = append(result.Implementations[ifaceEntry.qname], ImplementationMatch{
+					PackagePath: info.pkgPath,
+					TypeName:    named.Obj().Name(),
+					IsPointer:   false,
+					FilePath:    info.filePath,
+					Line:        info.line,
+				})
+				continue
+			}
+
+			ptr := types.NewPointer(unified)
+			if types.Implements(ptr, ifaceEntry.typ) {
+				result.Implementations[ifaceEntry.qname] = append(result.Implementations[ifaceEntry.qname], ImplementationMatch{
+					PackagePath: info.pkgPath,
+					TypeName:    named.Obj().Name(),
+					IsPointer:   true,
+					FilePath:    info.filePath,
+					Line:        info.line,
+				})
+			}
+		}
 	}
+}
 
-	// 处理主目录
-	if err := processDir(dirPath); err != nil {
-		return result, err
+// unifyTypeParams 把 named 自身的类型参数替换成 ifaceNamed 的类型参数（按位置一一对应），
+// 这样双方方法签名里引用的就是同一组 *types.TypeParam，types.Implements 才能在泛型场景下
+// 正确匹配（例如 Store[K,V] 对 Container[T] 的实现判定）。类型参数个数对不上时返回原始 named，
+// 按非泛型逻辑比较。
+func unifyTypeParams(named, ifaceNamed *types.Named) *types.Named {
+	tparams := named.TypeParams()
+	if tparams == nil || tparams.Len() == 0 || ifaceNamed == nil {
+		return named
+	}
+	ifaceTParams := ifaceNamed.TypeParams()
+	if ifaceTParams == nil || ifaceTParams.Len() != tparams.Len() {
+		return named
 	}
 
-	// 如果结果为空，尝试扫描相邻目录
-	if len(result.Packages) == 0 {
-		parentDir := filepath.Dir(dirPath)
-		if parentDir != dirPath {
-			// 处理父目录，尝试查找包
-			_ = processDir(parentDir)
-		}
+	targs := make([]types.Type, tparams.Len())
+	for i := 0; i < tparams.Len(); i++ {
+		targs[i] = ifaceTParams.At(i)
 	}
 
-	return result, nil
+	inst, err := types.Instantiate(nil, named.Origin(), targs, false)
+	if err != nil {
+		return named
+	}
+	instNamed, ok := inst.(*types.Named)
+	if !ok {
+		return named
+	}
+	return instNamed
+}
+
+// 解析指定目录下（及其依赖）的所有包，产出类型检查过的 ParseResult
+func parseDirectory(dirPath string) (ParseResult, error) {
+	pkgs, err := loadPackages(dirPath)
+	if err != nil {
+		return ParseResult{}, err
+	}
+	return buildParseResult(pkgs), nil
+}
+
+// buildParseResult 把已加载的包集合编译成最终的 ParseResult，供单目录和模块级扫描共用
+func buildParseResult(pkgs []*packages.Package) ParseResult {
+	result := ParseResult{
+		Packages:        make(map[string]PackageInfo),
+		Implementations: make(map[string][]ImplementationMatch),
+	}
+
+	namedIndex := extractFacts(pkgs, &result)
+	resolveImplementations(pkgs, namedIndex, &result)
+
+	return result
 }
 
 // 分析指定文件和相关包
@@ -279,12 +496,27 @@ func analyzeFile(filePath string) (ParseResult, error) {
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "用法: %s <go文件路径>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "用法: %s <go文件路径> | %s serve [--socket path] | %s --module/--root <目录> [--include glob]... [--exclude glob]...\n", os.Args[0], os.Args[0], os.Args[0])
 		os.Exit(1)
 	}
 
-	filePath := os.Args[1]
-	result, err := analyzeFile(filePath)
+	if os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "daemon 启动失败: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var result ParseResult
+	var err error
+
+	if opts, ok := parseScanFlags(os.Args[1:]); ok {
+		result, err = scanModule(opts)
+	} else {
+		result, err = analyzeFile(os.Args[1])
+	}
+
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "分析失败: %v\n", err)
 		os.Exit(1)
@@ -299,3 +531,37 @@ func main() {
 
 	fmt.Println(string(jsonResult))
 }
+
+// parseScanFlags 识别 --module/--root 形式的调用（两者同义），不是该形式时返回 ok=false
+func parseScanFlags(args []string) (scanOptions, bool) {
+	var opts scanOptions
+	matched := false
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--module", "--root":
+			if i+1 >= len(args) {
+				continue
+			}
+			opts.root = args[i+1]
+			matched = true
+			i++
+		case "--include":
+			if i+1 >= len(args) {
+				continue
+			}
+			opts.include = append(opts.include, args[i+1])
+			i++
+		case "--exclude":
+			if i+1 >= len(args) {
+				continue
+			}
+			opts.exclude = append(opts.exclude, args[i+1])
+			i++
+		case "--no-cache":
+			opts.noCache = true
+		}
+	}
+
+	return opts, matched
+}
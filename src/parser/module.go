@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+)
+
+// findModuleRoot 从 startDir 向上查找最近的 go.mod，返回其所在目录和 module path
+func findModuleRoot(startDir string) (root string, modulePath string, err error) {
+	dir := startDir
+	for {
+		gomod := filepath.Join(dir, "go.mod")
+		if data, readErr := os.ReadFile(gomod); readErr == nil {
+			mf, parseErr := modfile.Parse(gomod, data, nil)
+			if parseErr != nil {
+				return "", "", fmt.Errorf("解析 go.mod 失败: %w", parseErr)
+			}
+			modulePath = ""
+			if mf.Module != nil {
+				modulePath = mf.Module.Mod.Path
+			}
+			return dir, modulePath, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("在 %s 及其上级目录均未找到 go.mod", startDir)
+		}
+		dir = parent
+	}
+}
+
+// scanOptions 对应 --module/--root 扫描的命令行参数
+type scanOptions struct {
+	root    string
+	include []string
+	exclude []string
+	noCache bool
+}
+
+// matchesGlobs 判断 relPath 是否命中 globs 中的任意一条（空列表视为全部匹配）
+func matchesGlobs(relPath string, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	for _, g := range globs {
+		if ok, _ := filepath.Match(g, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterPackages 按 --include/--exclude 对已加载的包做裁剪
+func filterPackages(pkgs []*packages.Package, root string, include, exclude []string) []*packages.Package {
+	if len(include) == 0 && len(exclude) == 0 {
+		return pkgs
+	}
+
+	filtered := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		dir := pkg.PkgPath
+		if len(pkg.GoFiles) > 0 {
+			dir = filepath.Dir(pkg.GoFiles[0])
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			rel = dir
+		}
+
+		if len(include) > 0 && !matchesGlobs(rel, include) {
+			continue
+		}
+		if len(exclude) > 0 && matchesGlobs(rel, exclude) {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	return filtered
+}
+
+// fingerprintDir 按 include/exclude 枚举 root 下的 .go 文件，用 mtime + 大小算出缓存键，
+// 不依赖 packages.Load，以便在调用它之前就能判断缓存是否命中
+func fingerprintDir(root string, include, exclude []string) (string, error) {
+	var files []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(root, filepath.Dir(path))
+		if relErr != nil {
+			rel = filepath.Dir(path)
+		}
+		if len(include) > 0 && !matchesGlobs(rel, include) {
+			return nil
+		}
+		if len(exclude) > 0 && matchesGlobs(rel, exclude) {
+			return nil
+		}
+
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("枚举模块文件失败: %w", err)
+	}
+
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, f := range files {
+		info, statErr := os.Stat(f)
+		if statErr != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s:%d:%s\n", f, info.Size(), strconv.FormatInt(info.ModTime().UnixNano(), 10))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cacheFilePath 返回给定指纹对应的磁盘缓存文件路径，放在用户缓存目录下按 root 分桶，
+// 不放进被扫描的模块里
+func cacheFilePath(root, key string) string {
+	rootHash := sha256.Sum256([]byte(root))
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "ijump", hex.EncodeToString(rootHash[:8]), key+".json")
+}
+
+// loadCachedResult 尝试从磁盘缓存读取 ParseResult，未命中返回 ok=false
+func loadCachedResult(root, key string) (ParseResult, bool) {
+	data, err := os.ReadFile(cacheFilePath(root, key))
+	if err != nil {
+		return ParseResult{}, false
+	}
+	var result ParseResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return ParseResult{}, false
+	}
+	return result, true
+}
+
+// saveCachedResult 把 ParseResult 写入磁盘缓存，供下次同指纹的扫描直接复用
+func saveCachedResult(root, key string, result ParseResult) {
+	path := cacheFilePath(root, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "创建缓存目录失败: %v\n", err)
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "序列化缓存失败: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "写入缓存失败: %v\n", err)
+	}
+}
+
+// scanModule 扫描 opts.root 所在模块的全部包（遵循构建标签），按 import path 聚合结果
+func scanModule(opts scanOptions) (ParseResult, error) {
+	root, _, err := findModuleRoot(opts.root)
+	if err != nil {
+		return ParseResult{}, err
+	}
+
+	var key string
+	if !opts.noCache {
+		key, err = fingerprintDir(root, opts.include, opts.exclude)
+		if err == nil {
+			if cached, ok := loadCachedResult(root, key); ok {
+				return cached, nil
+			}
+		}
+	}
+
+	pkgs, err := loadPackagePatterns(root, []string{"./..."})
+	if err != nil {
+		return ParseResult{}, err
+	}
+	pkgs = filterPackages(pkgs, root, opts.include, opts.exclude)
+
+	result := buildParseResult(pkgs)
+	if !opts.noCache && key != "" {
+		saveCachedResult(root, key, result)
+	}
+	return result, nil
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// rpcRequest / rpcResponse 实现最小的 JSON-RPC 2.0 信封
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// daemon 维护一份常驻内存索引，按工作区根目录缓存已解析的 ParseResult
+type daemon struct {
+	mu       sync.RWMutex
+	results  map[string]ParseResult // 以工作区根目录为键
+	watcher  *fsnotify.Watcher
+	watchDir map[string]bool
+}
+
+func newDaemon() (*daemon, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建文件监视器失败: %w", err)
+	}
+
+	d := &daemon{
+		results:  make(map[string]ParseResult),
+		watcher:  watcher,
+		watchDir: make(map[string]bool),
+	}
+	go d.watchLoop()
+	return d, nil
+}
+
+// watchLoop 监听 fsnotify 事件，.go 文件的任何变更事件（不区分写入、创建、删除、改名或权限变更）都会触发，仅重新解析对应的工作区
+func (d *daemon) watchLoop() {
+	for {
+		select {
+		case event, ok := <-d.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Ext(event.Name) != ".go" {
+				continue
+			}
+			d.reparseContaining(event.Name)
+		case err, ok := <-d.watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "文件监视错误: %v\n", err)
+		}
+	}
+}
+
+// reparseContaining 重新解析包含发生变更文件的工作区
+func (d *daemon) reparseContaining(changedFile string) {
+	dir := filepath.Dir(changedFile)
+
+	d.mu.RLock()
+	var root string
+	for r := range d.results {
+		if within(dir, r) {
+			root = r
+			break
+		}
+	}
+	d.mu.RUnlock()
+
+	if root == "" {
+		return
+	}
+
+	result, err := parseDirectory(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "增量重新解析失败 %s: %v\n", root, err)
+		return
+	}
+
+	d.mu.Lock()
+	d.results[root] = result
+	d.mu.Unlock()
+}
+
+func within(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (len(rel) > 0 && rel[0] != '.')
+}
+
+// parseWorkspace 解析并缓存指定根目录，同时把该目录加入 fsnotify 监视列表
+func (d *daemon) parseWorkspace(root string) (ParseResult, error) {
+	result, err := parseDirectory(root)
+	if err != nil {
+		return result, err
+	}
+
+	d.mu.Lock()
+	d.results[root] = result
+	if !d.watchDir[root] {
+		if err := d.watcher.Add(root); err != nil {
+			fmt.Fprintf(os.Stderr, "添加监视目录失败 %s: %v\n", root, err)
+		} else {
+			d.watchDir[root] = true
+		}
+	}
+	d.mu.Unlock()
+
+	return result, nil
+}
+
+// implementationsOfParams / interfacesImplementedByParams 是对应 RPC 方法的入参
+type implementationsOfParams struct {
+	Root      string `json:"root"`
+	Interface string `json:"interface"`
+}
+
+type interfacesImplementedByParams struct {
+	Root string `json:"root"`
+	Type string `json:"type"`
+}
+
+// didChangeWatchedFilesParams 对应的 Files 只用来判断是否需要重新解析，不按文件精确裁剪
+type didChangeWatchedFilesParams struct {
+	Root  string   `json:"root"`
+	Files []string `json:"files"`
+}
+
+// anyGoFile 判断 files 里是否有至少一个 .go 文件，非 .go 变更不值得触发重新解析
+func anyGoFile(files []string) bool {
+	for _, f := range files {
+		if filepath.Ext(f) == ".go" {
+			return true
+		}
+	}
+	return false
+}
+
+// handle 按方法名分发一次 JSON-RPC 请求，返回 result 或 error
+func (d *daemon) handle(req rpcRequest) (interface{}, *rpcError) {
+	switch req.Method {
+	case "parseWorkspace":
+		var p struct {
+			Root string `json:"root"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}
+		}
+		result, err := d.parseWorkspace(p.Root)
+		if err != nil {
+			return nil, &rpcError{Code: -32000, Message: err.Error()}
+		}
+		return result, nil
+
+	case "implementationsOf":
+		var p implementationsOfParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}
+		}
+		d.mu.RLock()
+		result := d.results[p.Root]
+		d.mu.RUnlock()
+		return result.Implementations[p.Interface], nil
+
+	case "interfacesImplementedBy":
+		var p interfacesImplementedByParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}
+		}
+		d.mu.RLock()
+		result := d.results[p.Root]
+		d.mu.RUnlock()
+
+		var matches []string
+		for ifaceName, impls := range result.Implementations {
+			for _, impl := range impls {
+				if impl.TypeName == p.Type {
+					matches = append(matches, ifaceName)
+					break
+				}
+			}
+		}
+		return matches, nil
+
+	case "didChangeWatchedFiles":
+		var p didChangeWatchedFilesParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}
+		}
+		if !anyGoFile(p.Files) {
+			d.mu.RLock()
+			result := d.results[p.Root]
+			d.mu.RUnlock()
+			return result, nil
+		}
+		result, err := d.parseWorkspace(p.Root)
+		if err != nil {
+			return nil, &rpcError{Code: -32000, Message: err.Error()}
+		}
+		return result, nil
+
+	default:
+		return nil, &rpcError{Code: -32601, Message: "method not found: " + req.Method}
+	}
+}
+
+// serveConn 在单个连接（stdio 或 unix socket）上串行处理以换行分隔的 JSON-RPC 请求
+func (d *daemon) serveConn(rwc io.ReadWriteCloser) {
+	defer rwc.Close()
+
+	scanner := bufio.NewScanner(rwc)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(rwc)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			enc.Encode(rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: -32700, Message: "parse error"}})
+			continue
+		}
+
+		result, rpcErr := d.handle(req)
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+		if err := enc.Encode(resp); err != nil {
+			fmt.Fprintf(os.Stderr, "写入响应失败: %v\n", err)
+			return
+		}
+	}
+}
+
+// runServe 实现 `ijump serve` 子命令：在 stdio 或 unix socket 上常驻监听 JSON-RPC 请求
+func runServe(args []string) error {
+	socketPath := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--socket" && i+1 < len(args) {
+			socketPath = args[i+1]
+			i++
+		}
+	}
+
+	d, err := newDaemon()
+	if err != nil {
+		return err
+	}
+	defer d.watcher.Close()
+
+	if socketPath == "" {
+		d.serveConn(struct {
+			io.Reader
+			io.Writer
+			io.Closer
+		}{os.Stdin, os.Stdout, io.NopCloser(nil)})
+		return nil
+	}
+
+	os.Remove(socketPath)
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("监听 unix socket 失败: %w", err)
+	}
+	defer ln.Close()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "接受连接失败: %v\n", err)
+			continue
+		}
+		go d.serveConn(conn)
+	}
+}